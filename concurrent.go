@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// primeWorkers returns a sensible default worker count for genPrimeConcurrent: enough to
+// use every core, but at least one.
+func primeWorkers() int {
+	if n := runtime.NumCPU(); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// genPrimeConcurrent looks for a prime in [min, max] the same way genPrime does, but splits
+// the range into `workers` disjoint segments and searches them in parallel, returning as
+// soon as the first goroutine finds a prime. This is what makes generating the large primes
+// needed for 2048/4096-bit keys practical.
+func genPrimeConcurrent(min, max *big.Int, workers int) (*big.Int, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if min.Cmp(max) > 0 {
+		return nil, fmt.Errorf("invalid range [%s, %s]", min, max)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	span := new(big.Int).Sub(max, min)
+	span.Add(span, bigOne)
+	segSize := new(big.Int).Div(span, big.NewInt(int64(workers)))
+	if segSize.Sign() == 0 {
+		segSize = big.NewInt(1)
+	}
+
+	results := make(chan *big.Int, workers)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		segMin := new(big.Int).Add(min, new(big.Int).Mul(segSize, big.NewInt(int64(w))))
+		segMax := new(big.Int).Add(segMin, segSize)
+		segMax.Sub(segMax, bigOne)
+		if w == workers-1 || segMax.Cmp(max) > 0 {
+			segMax = new(big.Int).Set(max)
+		}
+		if segMin.Cmp(segMax) > 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(lo, hi *big.Int) {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			p, err := genPrime(lo, hi)
+			if err != nil {
+				return
+			}
+
+			select {
+			case results <- p:
+				cancel()
+			case <-ctx.Done():
+			}
+		}(segMin, segMax)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	if p, ok := <-results; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("no prime numbers found in range [%s, %s]", min, max)
+}
+
+// weakPrimePoolCap bounds how many primes GenerateWeakKeysPair remembers for reuse; a real
+// entropy failure would be caused by a small seed space, which this caps to emulate.
+const weakPrimePoolCap = 8
+
+var (
+	weakPrimePoolMu sync.Mutex
+	weakPrimePool   []*big.Int
+)
+
+// GenerateWeakKeysPair is a teaching aid, not a real key generator: with probability dupProb
+// it reuses a prime factor from a pool of previously generated primes instead of drawing a
+// fresh one, simulating the kind of entropy failure (e.g. a broken RNG at VM boot) that has
+// caused real-world RSA key collisions in the wild. Keys produced this way are fully
+// functional, but some fraction of them share a prime factor with another key from this
+// same pool, which BatchGCDSharedFactors can recover.
+func GenerateWeakKeysPair(dupProb float64) (PrivateKey, PublicKey, error) {
+	const bits = 64
+	halfBits := bits / 2
+	min := new(big.Int).Lsh(big.NewInt(1), uint(halfBits-1))
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(halfBits)), big.NewInt(1))
+
+	p, err := weakPrime(min, max, dupProb)
+	if err != nil {
+		return PrivateKey{}, PublicKey{}, fmt.Errorf("failed to generate prime p: %w", err)
+	}
+	var q *big.Int
+	for {
+		q, err = weakPrime(min, max, dupProb)
+		if err != nil {
+			return PrivateKey{}, PublicKey{}, fmt.Errorf("failed to generate prime q: %w", err)
+		}
+		if q.Cmp(p) != 0 {
+			break
+		}
+	}
+
+	n := new(big.Int).Mul(p, q)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, bigOne), new(big.Int).Sub(q, bigOne))
+
+	e := big.NewInt(65537)
+	if new(big.Int).GCD(nil, nil, e, phi).Cmp(bigOne) != 0 {
+		e, err = genPrime(big.NewInt(3), phi)
+		if err != nil {
+			return PrivateKey{}, PublicKey{}, fmt.Errorf("failed to generate public e: %w", err)
+		}
+	}
+
+	d := new(big.Int).ModInverse(e, phi)
+	if d == nil {
+		return PrivateKey{}, PublicKey{}, fmt.Errorf("e has no inverse mod phi")
+	}
+
+	public := PublicKey{n: n, e: e}
+	private := PrivateKey{PublicKey: public, d: d, p: p, q: q}
+	private.Precompute()
+
+	return private, public, nil
+}
+
+// weakPrime either reuses a prime from weakPrimePool (with probability dupProb) or generates
+// a fresh one and adds it to the pool.
+func weakPrime(min, max *big.Int, dupProb float64) (*big.Int, error) {
+	weakPrimePoolMu.Lock()
+	if len(weakPrimePool) > 0 {
+		roll, err := randFloat64()
+		if err != nil {
+			weakPrimePoolMu.Unlock()
+			return nil, err
+		}
+		if roll < dupProb {
+			idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(weakPrimePool))))
+			if err != nil {
+				weakPrimePoolMu.Unlock()
+				return nil, err
+			}
+			p := weakPrimePool[idx.Int64()]
+			weakPrimePoolMu.Unlock()
+			return p, nil
+		}
+	}
+	weakPrimePoolMu.Unlock()
+
+	p, err := genPrime(min, max)
+	if err != nil {
+		return nil, err
+	}
+
+	weakPrimePoolMu.Lock()
+	weakPrimePool = append(weakPrimePool, p)
+	if len(weakPrimePool) > weakPrimePoolCap {
+		weakPrimePool = weakPrimePool[1:]
+	}
+	weakPrimePoolMu.Unlock()
+
+	return p, nil
+}
+
+// randFloat64 returns a uniformly random float64 in [0, 1).
+func randFloat64() (float64, error) {
+	const precision = 1 << 53
+	n, err := rand.Int(rand.Reader, big.NewInt(precision))
+	if err != nil {
+		return 0, err
+	}
+	return float64(n.Int64()) / precision, nil
+}
+
+// BatchGCDSharedFactors demonstrates the batch-GCD attack: given a batch of public keys,
+// gcd(N_i, N_j) recovers their shared prime factor whenever two moduli were generated with
+// a common p or q, letting an attacker (or, here, the key's own owner) factor both keys
+// without ever attempting to factor either one directly.
+func BatchGCDSharedFactors(pubs []PublicKey) map[[2]int]*big.Int {
+	shared := make(map[[2]int]*big.Int)
+	for i := 0; i < len(pubs); i++ {
+		for j := i + 1; j < len(pubs); j++ {
+			g := new(big.Int).GCD(nil, nil, pubs[i].n, pubs[j].n)
+			if g.Cmp(bigOne) != 0 {
+				shared[[2]int{i, j}] = g
+			}
+		}
+	}
+	return shared
+}
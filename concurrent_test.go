@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_genPrimeConcurrent(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		p, err := genPrimeConcurrent(big.NewInt(100), big.NewInt(1000000), 4)
+		if err != nil {
+			t.Error("Failed to generate prime number: ", err)
+			continue
+		}
+		if !isProbablePrime(p) {
+			t.Errorf("%s is not prime", p)
+		}
+		if p.Cmp(big.NewInt(100)) < 0 || p.Cmp(big.NewInt(1000000)) > 0 {
+			t.Errorf("%s is out of range", p)
+		}
+	}
+}
+
+func TestGenerateWeakKeysPairRoundTrip(t *testing.T) {
+	private, public, err := GenerateWeakKeysPair(0.9)
+	if err != nil {
+		t.Fatal("Failed to generate weak keys pair: ", err)
+	}
+
+	plaintext := "weak keys still have to work"
+	ciphertext := Encrypt(plaintext, public)
+	if Decrypt(ciphertext, private) != plaintext {
+		t.Error("round trip failed for weak key pair")
+	}
+}
+
+func TestGenerateWeakKeysPairSharesFactors(t *testing.T) {
+	const n = 20
+	pubs := make([]PublicKey, n)
+	for i := range pubs {
+		_, public, err := GenerateWeakKeysPair(0.9)
+		if err != nil {
+			t.Fatal("Failed to generate weak keys pair: ", err)
+		}
+		pubs[i] = public
+	}
+
+	if len(BatchGCDSharedFactors(pubs)) == 0 {
+		t.Error("expected at least one shared prime factor among weak keys with dupProb=0.9")
+	}
+}
+
+func TestBatchGCDSharedFactorsNoneForIndependentKeys(t *testing.T) {
+	const n = 5
+	pubs := make([]PublicKey, n)
+	for i := range pubs {
+		_, public, err := GenerateKeysPairBits(64)
+		if err != nil {
+			t.Fatal("Failed to generate keys pair: ", err)
+		}
+		pubs[i] = public
+	}
+
+	if shared := BatchGCDSharedFactors(pubs); len(shared) != 0 {
+		t.Errorf("expected no shared factors among independently generated keys, got %v", shared)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import "math/big"
+
+// PrecomputedValues holds the CRT parameters derived from a PrivateKey's primes, mirroring
+// crypto/rsa.PrecomputedValues. Computing m mod p and m mod q separately and recombining is
+// about 4x faster than a single exponentiation mod n, since each half-sized exponentiation
+// costs roughly 1/8th of the full one.
+type PrecomputedValues struct {
+	Dp, Dq *big.Int // D mod (p-1), D mod (q-1)
+	Qinv   *big.Int // Q^-1 mod P
+}
+
+// Precompute fills in priv.Precomputed from priv.p, priv.q and priv.d, so that Decrypt and
+// DecryptOAEP can use the Chinese Remainder Theorem instead of a full-width exponentiation.
+// It is a no-op if p or q is unknown.
+func (priv *PrivateKey) Precompute() {
+	if priv.p == nil || priv.q == nil {
+		return
+	}
+
+	pMinus1 := new(big.Int).Sub(priv.p, bigOne)
+	qMinus1 := new(big.Int).Sub(priv.q, bigOne)
+
+	priv.Precomputed.Dp = new(big.Int).Mod(priv.d, pMinus1)
+	priv.Precomputed.Dq = new(big.Int).Mod(priv.d, qMinus1)
+	priv.Precomputed.Qinv = new(big.Int).ModInverse(priv.q, priv.p)
+}
+
+// decryptCRT computes c^d mod n, using the Chinese Remainder Theorem when priv carries
+// precomputed CRT values and falling back to plain modular exponentiation otherwise.
+func decryptCRT(priv PrivateKey, c *big.Int) *big.Int {
+	if priv.p == nil || priv.q == nil || priv.Precomputed.Qinv == nil {
+		return expMod(c, priv.d, priv.n)
+	}
+
+	m1 := expMod(c, priv.Precomputed.Dp, priv.p)
+	m2 := expMod(c, priv.Precomputed.Dq, priv.q)
+
+	h := new(big.Int).Sub(m1, m2)
+	h.Mul(h, priv.Precomputed.Qinv)
+	h.Mod(h, priv.p)
+
+	m := new(big.Int).Mul(h, priv.q)
+	m.Add(m, m2)
+
+	return m
+}
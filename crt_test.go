@@ -0,0 +1,29 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDecryptCRTMatchesPlain(t *testing.T) {
+	private, public, err := GenerateKeysPairBits(256)
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
+	if private.Precomputed.Qinv == nil {
+		t.Fatal("GenerateKeysPairBits did not precompute CRT values")
+	}
+
+	c := expMod(big.NewInt(12345), public.e, public.n)
+
+	gotCRT := decryptCRT(private, c)
+
+	plain := private
+	plain.p, plain.q = nil, nil
+	plain.Precomputed = PrecomputedValues{}
+	gotPlain := decryptCRT(plain, c)
+
+	if gotCRT.Cmp(gotPlain) != 0 {
+		t.Errorf("CRT decryption (%s) does not match plain decryption (%s)", gotCRT, gotPlain)
+	}
+}
@@ -2,53 +2,102 @@ package main
 
 import (
 	"bytes"
+	"crypto/rand"
 	"fmt"
-	"math/rand"
-	"time"
+	"math/big"
 )
 
 type PrivateKey struct {
-	// see GenerateKeysPair for n and d values
-	n, d int
+	PublicKey
+	// see GenerateKeysPair for the d value
+	d *big.Int
+	// p and q are the two primes factoring n, if known. They are required for Precompute
+	// and therefore for CRT-accelerated decryption; a key built without them (e.g. parsed
+	// from a source that only carries n and d) falls back to plain modular exponentiation.
+	p, q *big.Int
+
+	Precomputed PrecomputedValues
 }
 
 type PublicKey struct {
 	// see GenerateKeysPair for n and e values
-	n, e int
+	n, e *big.Int
 }
 
-// Generates a private and public key pair.
+// Generates a private and public key pair using a toy-sized, fast-to-generate modulus.
 // Note that in theory, you cannot derive the public key knowing the private key. You can only generate them as a pair.
 // In practice, the RSA private key file may contain intermediate values that allow computing the public key.
 // See https://stackoverflow.com/a/1373088/7403220
 func GenerateKeysPair() (private PrivateKey, public PublicKey, err error) {
-	// generate two prime numbers that fit into a 16-bit integer and whose product fits into a 32-bit integer
-	p := genPrime(100, 10000)
-	q := genPrime(100, 10000)
-	if p < 0 || q < 0 {
-		return PrivateKey{}, PublicKey{}, fmt.Errorf("Failed to generate prime numbers")
+	return GenerateKeysPairBits(64)
+}
+
+// Generates a private and public key pair whose modulus is approximately `bits` bits long
+// (e.g. 1024, 2048, 4096), by combining two primes of roughly bits/2 each.
+func GenerateKeysPairBits(bits int) (private PrivateKey, public PublicKey, err error) {
+	if bits < 24 {
+		// Encrypt/Decrypt pack a 16-bit value (t = (a<<8)|b) per block, so the modulus
+		// needs enough headroom above 2^16 for the round trip to survive; 24 bits leaves
+		// a comfortable margin even with unlucky prime draws.
+		return PrivateKey{}, PublicKey{}, fmt.Errorf("bits must be at least 24, got %d", bits)
+	}
+
+	halfBits := bits / 2
+	min := new(big.Int).Lsh(big.NewInt(1), uint(halfBits-1))
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(halfBits)), big.NewInt(1))
+
+	workers := primeWorkers()
+	p, err := genPrimeConcurrent(min, max, workers)
+	if err != nil {
+		return PrivateKey{}, PublicKey{}, fmt.Errorf("failed to generate prime p: %w", err)
+	}
+	var q *big.Int
+	for {
+		q, err = genPrimeConcurrent(min, max, workers)
+		if err != nil {
+			return PrivateKey{}, PublicKey{}, fmt.Errorf("failed to generate prime q: %w", err)
+		}
+		if q.Cmp(p) != 0 {
+			break
+		}
+	}
+
+	n := new(big.Int).Mul(p, q)
+	pMinus1 := new(big.Int).Sub(p, big.NewInt(1))
+	qMinus1 := new(big.Int).Sub(q, big.NewInt(1))
+	phi := new(big.Int).Mul(pMinus1, qMinus1)
+
+	// 65537 is the standard choice: small enough for fast encryption, large enough to
+	// avoid the low-exponent attacks that plague e=3. Fall back to searching for a prime
+	// e if it happens not to be coprime with phi (astronomically unlikely for random p, q).
+	e := big.NewInt(65537)
+	gcd := new(big.Int).GCD(nil, nil, e, phi)
+	if gcd.Cmp(big.NewInt(1)) != 0 {
+		e, err = genPrime(big.NewInt(3), phi)
+		if err != nil {
+			return PrivateKey{}, PublicKey{}, fmt.Errorf("failed to generate public e: %w", err)
+		}
 	}
 
-	n := p * q
-	phi := (p - 1) * (q - 1)
-	// e < n, and e and phi must be coprimes. The simpliest way is to find a prime in range ( n/3, (p-1)(q-1) )
-	// it is slow, as we are trying to generate quite a large prime
-	e := genPrime(n/3+1, phi)
-	if e < 0 {
-		return PrivateKey{}, PublicKey{}, fmt.Errorf("Failed to generate public e")
+	d := new(big.Int).ModInverse(e, phi)
+	if d == nil {
+		return PrivateKey{}, PublicKey{}, fmt.Errorf("e has no inverse mod phi")
 	}
-	// we need only lx value
-	_, d, _ := multInverse(e, phi)
 
-	return PrivateKey{n: n, d: d}, PublicKey{n: n, e: e}, nil
+	public = PublicKey{n: n, e: e}
+	private = PrivateKey{PublicKey: public, d: d, p: p, q: q}
+	private.Precompute()
+
+	return private, public, nil
 }
 
-// Encrypts a plaintext string using a public key and returns a slice of integers.
+// Encrypts a plaintext string using a public key and returns a slice of big integers.
 // We use a slice of integers for plaintexts as it has no practical use to store them as strings.
 // Outputs different ciphertext each time as we use randomized padding, a countermeasure against some kinds of attacks.
-// See OAEP (https://en.wikipedia.org/wiki/Optimal_asymmetric_encryption_padding) for more information.
-func Encrypt(plaintext string, publicKey PublicKey) []int {
-	res := make([]int, 0)
+// See OAEP (https://en.wikipedia.org/wiki/Optimal_asymmetric_encryption_padding) for more information,
+// and EncryptOAEP for a real implementation of it.
+func Encrypt(plaintext string, publicKey PublicKey) []*big.Int {
+	res := make([]*big.Int, 0)
 	buf := bytes.NewBufferString(plaintext)
 	for {
 		p, err := buf.ReadByte()
@@ -56,25 +105,29 @@ func Encrypt(plaintext string, publicKey PublicKey) []int {
 			break
 		}
 		// randomized padding
-		r := byte(rand.Intn(255))
+		rb := make([]byte, 1)
+		if _, err := rand.Read(rb); err != nil {
+			panic("rsa-toy: failed to read random byte: " + err.Error())
+		}
+		r := rb[0]
 		a := hash1(r) ^ p
 		b := hash2(a) ^ r
 
-		t := (int(a) << 8) | int(b)
+		t := (int64(a) << 8) | int64(b)
 
-		c := expMod(int(t), publicKey.e, publicKey.n)
+		c := expMod(big.NewInt(t), publicKey.e, publicKey.n)
 		res = append(res, c)
 	}
 
 	return res
 }
 
-// This function decrypts a given ciphertext (in the form of a slice of integers) using the private key,
+// This function decrypts a given ciphertext (in the form of a slice of big integers) using the private key,
 // and returns the plaintext as a string.
-func Decrypt(ciphertext []int, privateKey PrivateKey) string {
+func Decrypt(ciphertext []*big.Int, privateKey PrivateKey) string {
 	buf := bytes.NewBufferString("")
 	for _, c := range ciphertext {
-		t := expMod(c, privateKey.d, privateKey.n)
+		t := decryptCRT(privateKey, c).Int64()
 		a := byte(t >> 8)
 		b := byte(t) & 0b11111111
 		r := hash2(a) ^ b
@@ -113,84 +166,114 @@ func hash2(x byte) byte {
 }
 
 // Finds Base^Exp mod Mod efficiently
-func expMod(base, exp, mod int) int {
-	res := 1
-	for exp > 0 {
-		if exp%2 == 1 {
-			res = (res * base) % mod
-			exp -= 1
-		} else {
-			base = base * base % mod
-			exp /= 2
-		}
-	}
-
-	return res
+func expMod(base, exp, mod *big.Int) *big.Int {
+	return new(big.Int).Exp(base, exp, mod)
 }
 
 // Finds gcd(a,b), lx, ly that gcd(a, b) = lx * a + ly * b
-// Based on https://gist.github.com/JekaDeka/c9b0f5da16625e3c7bd1033356354579
-func multInverse(a, b int) (int, int, int) {
-	x := 0
-	y := 1
-	lx := 1
-	ly := 0
-	oa := a
-	ob := b
-	for b != 0 {
-		q := a / b
-		a, b = b, a%b
-		x, lx = lx-q*x, x
-		y, ly = ly-q*y, y
+func multInverse(a, b *big.Int) (gcd, lx, ly *big.Int) {
+	lx = new(big.Int)
+	ly = new(big.Int)
+	gcd = new(big.Int).GCD(lx, ly, a, b)
+	return gcd, lx, ly
+}
+
+var (
+	bigOne   = big.NewInt(1)
+	bigTwo   = big.NewInt(2)
+	bigThree = big.NewInt(3)
+)
+
+// Generates a random prime number within the specified range.
+// Candidates are drawn at random from [min, max] and the nearest prime in either
+// direction is returned, using a Miller-Rabin primality test in place of the sieve
+// this used to build, which made memory usage scale with max.
+func genPrime(min, max *big.Int) (*big.Int, error) {
+	if min.Cmp(max) > 0 {
+		return nil, fmt.Errorf("invalid range [%s, %s]", min, max)
 	}
 
-	if lx < 0 {
-		lx += ob
+	span := new(big.Int).Sub(max, min)
+	span.Add(span, bigOne)
+	offset, err := rand.Int(rand.Reader, span)
+	if err != nil {
+		return nil, err
 	}
-	if ly < 0 {
-		ly += oa
+	x := new(big.Int).Add(min, offset)
+
+	for i := big.NewInt(0); ; i.Add(i, bigOne) {
+		hi := new(big.Int).Add(x, i)
+		lo := new(big.Int).Sub(x, i)
+		hiInRange := hi.Cmp(max) <= 0
+		loInRange := lo.Cmp(min) >= 0
+		if !hiInRange && !loInRange {
+			break
+		}
+		if hiInRange && isProbablePrime(hi) {
+			return hi, nil
+		}
+		if loInRange && isProbablePrime(lo) {
+			return lo, nil
+		}
 	}
 
-	return a, lx, ly
+	// no prime numbers within the given range
+	return nil, fmt.Errorf("no prime numbers found in range [%s, %s]", min, max)
 }
 
-// Generates a random prime number within the specified range using the Eratosthenes sieve algorithm
-// Slow and memory-consuming but very simple.
-func genPrime(min, max int) int {
-	// 0 - prime, 1 - not prime
-	sieve := make([]int8, max+1)
-	sieve[1] = 1
-	for i := 2; i <= max/2+1; i++ {
-		if sieve[i] == 1 {
-			continue
+// Miller-Rabin primality test. Returns true if n is (almost certainly) prime;
+// 20 rounds keeps the probability of a false positive astronomically low.
+func isProbablePrime(n *big.Int) bool {
+	if n.Sign() <= 0 || n.Cmp(bigTwo) < 0 {
+		return false
+	}
+	if n.Cmp(bigTwo) == 0 || n.Cmp(bigThree) == 0 {
+		return true
+	}
+	if n.Bit(0) == 0 {
+		return false
+	}
+
+	const rounds = 20
+
+	nMinus1 := new(big.Int).Sub(n, bigOne)
+	d := new(big.Int).Set(nMinus1)
+	s := 0
+	for d.Bit(0) == 0 {
+		d.Rsh(d, 1)
+		s++
+	}
+
+	nMinus3 := new(big.Int).Sub(n, big.NewInt(3))
+	for i := 0; i < rounds; i++ {
+		a, err := rand.Int(rand.Reader, nMinus3)
+		if err != nil {
+			return false
 		}
+		a.Add(a, bigTwo) // a in [2, n-2]
 
-		for j := i * 2; j <= max; j += i {
-			sieve[j] = 1
+		x := new(big.Int).Exp(a, d, n)
+		if x.Cmp(bigOne) == 0 || x.Cmp(nMinus1) == 0 {
+			continue
 		}
-	}
 
-	// pick a random prime from the sieve within a range
-	// we select random x and search for the nearest prime both ways
-	x := rand.Intn(max-min+1) + min
-	i := 0
-	for x+i <= max || x-i >= min {
-		if x+i <= max && sieve[x+i] == 0 {
-			return x + i
+		composite := true
+		for r := 0; r < s-1; r++ {
+			x.Exp(x, bigTwo, n)
+			if x.Cmp(nMinus1) == 0 {
+				composite = false
+				break
+			}
 		}
-		if x-i >= min && sieve[x-i] == 0 {
-			return x - i
+		if composite {
+			return false
 		}
-		i++
 	}
 
-	// no prime numbers within the given range
-	return -1
+	return true
 }
 
 func main() {
-	rand.Seed(time.Now().UnixMicro())
-
 	private, public, err := GenerateKeysPair()
 	fmt.Printf("Private key: %v, public key: %v\n", private, public)
 	if err != nil {
@@ -1,14 +1,16 @@
 package main
 
 import (
-	"math"
+	"math/big"
 	"reflect"
 	"testing"
 )
 
 func TestEncryptDecrypt(t *testing.T) {
-	private := PrivateKey{65473, 75827}
-	public := PublicKey{75827, 60457}
+	private, public, err := GenerateKeysPair()
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
 
 	plaintext := "Neque porro quisquam est, qui dolorem ipsum quia dolor sit amet, consectetur, adipisci velit, sed quia non numquam eius modi tempora incidunt ut labore et dolore magnam aliquam quaerat voluptatem"
 	ciphertext := Encrypt(plaintext, public)
@@ -20,7 +22,10 @@ func TestEncryptDecrypt(t *testing.T) {
 }
 
 func TestEncryptRandomized(t *testing.T) {
-	public := PublicKey{75827, 60457}
+	_, public, err := GenerateKeysPair()
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
 
 	plaintext := "Neque porro quisquam est, qui dolorem ipsum quia dolor sit amet, consectetur, adipisci velit, sed quia non numquam eius modi tempora incidunt ut labore et dolore magnam aliquam quaerat voluptatem"
 	ciphertext1 := Encrypt(plaintext, public)
@@ -40,30 +45,60 @@ func TestGenerateKeysPair(t *testing.T) {
 	}
 }
 
+func TestGenerateKeysPairBits(t *testing.T) {
+	for _, bits := range []int{32, 64, 128} {
+		private, public, err := GenerateKeysPairBits(bits)
+		if err != nil {
+			t.Fatalf("Failed to generate %d-bit keys pair: %v", bits, err)
+		}
+		if got := public.n.BitLen(); got < bits-2 || got > bits+1 {
+			t.Errorf("expected modulus around %d bits, got %d", bits, got)
+		}
+
+		plaintext := "hello, rsa"
+		ciphertext := Encrypt(plaintext, public)
+		if Decrypt(ciphertext, private) != plaintext {
+			t.Errorf("round trip failed for %d-bit keys", bits)
+		}
+	}
+}
+
+func TestGenerateKeysPairBits_tooSmall(t *testing.T) {
+	if _, _, err := GenerateKeysPairBits(16); err == nil {
+		t.Error("expected error for a 16-bit modulus, which can't hold Encrypt's 16-bit blocks")
+	}
+}
+
+func Test_isProbablePrime_small(t *testing.T) {
+	for n, want := range map[int64]bool{2: true, 3: true, 4: false, 5: true} {
+		if got := isProbablePrime(big.NewInt(n)); got != want {
+			t.Errorf("isProbablePrime(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
 func Test_genPrime_small(t *testing.T) {
 	for i := 0; i < 100; i++ {
-		p := genPrime(100, 1000)
-		if p < 0 {
-			t.Error("Failed to generate prime number")
+		p, err := genPrime(big.NewInt(100), big.NewInt(1000))
+		if err != nil {
+			t.Error("Failed to generate prime number: ", err)
+			continue
 		}
-		for d := 2; d < int(math.Sqrt(float64(p)))+1; d++ {
-			if p%d == 0 {
-				t.Errorf("%d is not prime (divided by %d)", p, d)
-			}
+		if !isProbablePrime(p) {
+			t.Errorf("%s is not prime", p)
 		}
 	}
 }
 
 func Test_genPrime_big(t *testing.T) {
 	for i := 0; i < 100; i++ {
-		p := genPrime(100, 1000000)
-		if p < 0 {
-			t.Error("Failed to generate prime number")
+		p, err := genPrime(big.NewInt(100), big.NewInt(1000000))
+		if err != nil {
+			t.Error("Failed to generate prime number: ", err)
+			continue
 		}
-		for d := 2; d < int(math.Sqrt(float64(p)))+1; d++ {
-			if p%d == 0 {
-				t.Errorf("%d is not prime (divided by %d)", p, d)
-			}
+		if !isProbablePrime(p) {
+			t.Errorf("%s is not prime", p)
 		}
 	}
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+)
+
+// ErrDecryption is returned whenever an OAEP ciphertext fails to decode, whatever the
+// underlying reason (wrong key, corrupted ciphertext, wrong label, ...). Collapsing every
+// failure into this single generic error is deliberate: a more specific error would let an
+// attacker probe the padding and mount a Manger-style chosen-ciphertext attack.
+var ErrDecryption = errors.New("rsa-toy: decryption error")
+
+// EncryptOAEP encrypts msg using RSAES-OAEP as specified in PKCS#1 v2. Unlike Encrypt, which
+// pads and encrypts one plaintext byte per RSA block, this packs as many plaintext bytes as
+// will fit into a single block, the way real RSA implementations do.
+//
+// random is the source of randomness for the OAEP seed. label is optional and, if present,
+// must be reproduced when decrypting.
+func EncryptOAEP(h hash.Hash, random io.Reader, pub PublicKey, msg, label []byte) ([]byte, error) {
+	h.Reset()
+	k := (pub.n.BitLen() + 7) / 8
+	hLen := h.Size()
+
+	if len(msg) > k-2*hLen-2 {
+		return nil, errors.New("rsa-toy: message too long for RSA public key size")
+	}
+
+	h.Write(label)
+	lHash := h.Sum(nil)
+
+	em := make([]byte, k)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+
+	copy(db[:hLen], lHash)
+	db[len(db)-len(msg)-1] = 1
+	copy(db[len(db)-len(msg):], msg)
+
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, err
+	}
+
+	dbMask := mgf1(seed, len(db), h)
+	xorBytes(db, dbMask)
+
+	seedMask := mgf1(db, len(seed), h)
+	xorBytes(seed, seedMask)
+
+	m := new(big.Int).SetBytes(em)
+	c := expMod(m, pub.e, pub.n)
+
+	return leftPad(c.Bytes(), k), nil
+}
+
+// DecryptOAEP reverses EncryptOAEP. random is accepted for API symmetry with EncryptOAEP and
+// with crypto/rsa (which uses it for blinding); this toy implementation does not need it.
+func DecryptOAEP(h hash.Hash, random io.Reader, priv PrivateKey, ciphertext, label []byte) ([]byte, error) {
+	h.Reset()
+	k := (priv.n.BitLen() + 7) / 8
+	hLen := h.Size()
+
+	if len(ciphertext) != k || k < 2*hLen+2 {
+		return nil, ErrDecryption
+	}
+
+	c := new(big.Int).SetBytes(ciphertext)
+	if c.Cmp(priv.n) >= 0 {
+		return nil, ErrDecryption
+	}
+
+	m := decryptCRT(priv, c)
+	em := leftPad(m.Bytes(), k)
+
+	firstByteIsZero := subtle.ConstantTimeByteEq(em[0], 0)
+
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+
+	seedMask := mgf1(db, hLen, h)
+	xorBytes(seed, seedMask)
+
+	dbMask := mgf1(seed, len(db), h)
+	xorBytes(db, dbMask)
+
+	h.Reset()
+	h.Write(label)
+	lHash := h.Sum(nil)
+	lHashGood := subtle.ConstantTimeCompare(db[:hLen], lHash)
+
+	// Find the 0x01 separator following the zero padding, without branching on secret data.
+	rest := db[hLen:]
+	lookingForIndex := 1
+	index := 0
+	invalid := 0
+	for i := 0; i < len(rest); i++ {
+		equals0 := subtle.ConstantTimeByteEq(rest[i], 0)
+		equals1 := subtle.ConstantTimeByteEq(rest[i], 1)
+		index = subtle.ConstantTimeSelect(lookingForIndex&equals1, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(equals1, 0, lookingForIndex)
+		invalid = subtle.ConstantTimeSelect(lookingForIndex&(1-equals0)&(1-equals1), 1, invalid)
+	}
+
+	if firstByteIsZero == 0 || lHashGood == 0 || invalid == 1 || lookingForIndex == 1 {
+		return nil, ErrDecryption
+	}
+
+	return rest[index+1:], nil
+}
+
+// mgf1 is the MGF1 mask generation function from PKCS#1, built on top of an arbitrary hash.
+func mgf1(seed []byte, length int, h hash.Hash) []byte {
+	var out bytes.Buffer
+	var counter [4]byte
+
+	for out.Len() < length {
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter[:])
+		out.Write(h.Sum(nil))
+		incCounter(&counter)
+	}
+
+	return out.Bytes()[:length]
+}
+
+func incCounter(c *[4]byte) {
+	for i := 3; i >= 0; i-- {
+		c[i]++
+		if c[i] != 0 {
+			return
+		}
+	}
+}
+
+// xorBytes XORs src into dst in place. dst and src must have the same length.
+func xorBytes(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// leftPad returns b zero-padded on the left to size bytes. b must not be longer than size.
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestEncryptDecryptOAEP(t *testing.T) {
+	private, public, err := GenerateKeysPairBits(1024)
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
+
+	msg := []byte("a short message that fits in one OAEP block")
+	label := []byte("test label")
+
+	ciphertext, err := EncryptOAEP(sha256.New(), rand.Reader, public, msg, label)
+	if err != nil {
+		t.Fatal("EncryptOAEP failed: ", err)
+	}
+
+	plaintext, err := DecryptOAEP(sha256.New(), rand.Reader, private, ciphertext, label)
+	if err != nil {
+		t.Fatal("DecryptOAEP failed: ", err)
+	}
+
+	if !bytes.Equal(plaintext, msg) {
+		t.Errorf("got %q, want %q", plaintext, msg)
+	}
+}
+
+func TestDecryptOAEPWrongLabel(t *testing.T) {
+	private, public, err := GenerateKeysPairBits(1024)
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
+
+	msg := []byte("hello")
+	ciphertext, err := EncryptOAEP(sha256.New(), rand.Reader, public, msg, []byte("label-a"))
+	if err != nil {
+		t.Fatal("EncryptOAEP failed: ", err)
+	}
+
+	if _, err := DecryptOAEP(sha256.New(), rand.Reader, private, ciphertext, []byte("label-b")); err != ErrDecryption {
+		t.Errorf("expected ErrDecryption, got %v", err)
+	}
+}
+
+func TestEncryptOAEPMessageTooLong(t *testing.T) {
+	_, public, err := GenerateKeysPairBits(1024)
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
+
+	k := (public.n.BitLen() + 7) / 8
+	tooLong := make([]byte, k)
+
+	if _, err := EncryptOAEP(sha256.New(), rand.Reader, public, tooLong, nil); err == nil {
+		t.Error("expected error for oversized message, got nil")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+)
+
+var oidRSAEncryption = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+
+// pkcs1PrivateKey is the ASN.1 structure of RFC 8017 Appendix A.1.2, the same layout
+// OpenSSL writes for "RSA PRIVATE KEY" PEM blocks.
+type pkcs1PrivateKey struct {
+	Version int
+	N       *big.Int
+	E       *big.Int
+	D       *big.Int
+	P       *big.Int
+	Q       *big.Int
+	Dp      *big.Int
+	Dq      *big.Int
+	Qinv    *big.Int
+}
+
+// pkcs1PublicKey is the ASN.1 structure of RFC 8017 Appendix A.1.1.
+type pkcs1PublicKey struct {
+	N *big.Int
+	E *big.Int
+}
+
+// pkixPublicKey is the SubjectPublicKeyInfo structure used by "PUBLIC KEY" PEM blocks.
+type pkixPublicKey struct {
+	Algo      pkixAlgorithmIdentifier
+	BitString asn1.BitString
+}
+
+type pkixAlgorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+// MarshalPKCS1PrivateKey encodes priv as a PKCS#1 DER-encoded RSAPrivateKey, the same
+// structure OpenSSL produces for `openssl genrsa`.
+func MarshalPKCS1PrivateKey(priv PrivateKey) []byte {
+	dp, dq, qinv := priv.Precomputed.Dp, priv.Precomputed.Dq, priv.Precomputed.Qinv
+	if dp == nil || dq == nil || qinv == nil {
+		dp, dq, qinv = big.NewInt(0), big.NewInt(0), big.NewInt(0)
+	}
+
+	der, err := asn1.Marshal(pkcs1PrivateKey{
+		Version: 0,
+		N:       priv.n,
+		E:       priv.e,
+		D:       priv.d,
+		P:       priv.p,
+		Q:       priv.q,
+		Dp:      dp,
+		Dq:      dq,
+		Qinv:    qinv,
+	})
+	if err != nil {
+		// N, E, D, P and Q are always non-nil for a key that came out of GenerateKeysPair,
+		// so asn1.Marshal can only fail here if that invariant is broken by the caller.
+		panic("rsa-toy: malformed PrivateKey: " + err.Error())
+	}
+
+	return der
+}
+
+// ParsePKCS1PrivateKey parses a DER-encoded PKCS#1 RSAPrivateKey, as produced by
+// MarshalPKCS1PrivateKey or by `openssl genrsa`.
+func ParsePKCS1PrivateKey(der []byte) (PrivateKey, error) {
+	var key pkcs1PrivateKey
+	rest, err := asn1.Unmarshal(der, &key)
+	if err != nil {
+		return PrivateKey{}, errors.New("rsa-toy: failed to parse PKCS1 private key: " + err.Error())
+	}
+	if len(rest) != 0 {
+		return PrivateKey{}, errors.New("rsa-toy: trailing data after PKCS1 private key")
+	}
+	if key.N == nil || key.E == nil || key.D == nil || key.P == nil || key.Q == nil {
+		return PrivateKey{}, errors.New("rsa-toy: PKCS1 private key missing required fields")
+	}
+
+	priv := PrivateKey{
+		PublicKey: PublicKey{n: key.N, e: key.E},
+		d:         key.D,
+		p:         key.P,
+		q:         key.Q,
+	}
+	priv.Precompute()
+
+	return priv, nil
+}
+
+// MarshalPKCS1PublicKey encodes pub as a PKCS#1 DER-encoded RSAPublicKey.
+func MarshalPKCS1PublicKey(pub PublicKey) []byte {
+	der, err := asn1.Marshal(pkcs1PublicKey{N: pub.n, E: pub.e})
+	if err != nil {
+		panic("rsa-toy: malformed PublicKey: " + err.Error())
+	}
+	return der
+}
+
+// ParsePKCS1PublicKey parses a DER-encoded PKCS#1 RSAPublicKey.
+func ParsePKCS1PublicKey(der []byte) (PublicKey, error) {
+	var key pkcs1PublicKey
+	rest, err := asn1.Unmarshal(der, &key)
+	if err != nil {
+		return PublicKey{}, errors.New("rsa-toy: failed to parse PKCS1 public key: " + err.Error())
+	}
+	if len(rest) != 0 {
+		return PublicKey{}, errors.New("rsa-toy: trailing data after PKCS1 public key")
+	}
+	if key.N == nil || key.E == nil {
+		return PublicKey{}, errors.New("rsa-toy: PKCS1 public key missing required fields")
+	}
+
+	return PublicKey{n: key.N, e: key.E}, nil
+}
+
+// MarshalPKIXPublicKey encodes pub as a DER-encoded PKIX SubjectPublicKeyInfo, the
+// structure OpenSSL and most other tools use for "PUBLIC KEY" PEM blocks.
+func MarshalPKIXPublicKey(pub PublicKey) ([]byte, error) {
+	pkcs1Der := MarshalPKCS1PublicKey(pub)
+
+	return asn1.Marshal(pkixPublicKey{
+		Algo: pkixAlgorithmIdentifier{
+			Algorithm:  oidRSAEncryption,
+			Parameters: asn1.RawValue{Tag: asn1.TagNull},
+		},
+		BitString: asn1.BitString{Bytes: pkcs1Der, BitLength: 8 * len(pkcs1Der)},
+	})
+}
+
+// ParsePKIXPublicKey parses a DER-encoded PKIX SubjectPublicKeyInfo containing an RSA key.
+func ParsePKIXPublicKey(der []byte) (PublicKey, error) {
+	var spki pkixPublicKey
+	rest, err := asn1.Unmarshal(der, &spki)
+	if err != nil {
+		return PublicKey{}, errors.New("rsa-toy: failed to parse PKIX public key: " + err.Error())
+	}
+	if len(rest) != 0 {
+		return PublicKey{}, errors.New("rsa-toy: trailing data after PKIX public key")
+	}
+	if !spki.Algo.Algorithm.Equal(oidRSAEncryption) {
+		return PublicKey{}, errors.New("rsa-toy: PKIX public key is not an RSA key")
+	}
+
+	return ParsePKCS1PublicKey(spki.BitString.Bytes)
+}
+
+// EncodePEM wraps der in a PEM block of the given type, e.g. "RSA PRIVATE KEY" or
+// "PUBLIC KEY".
+func EncodePEM(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+// DecodePEM parses the first PEM block out of data, returning its type and DER bytes.
+func DecodePEM(data []byte) (blockType string, der []byte, err error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", nil, errors.New("rsa-toy: failed to decode PEM block")
+	}
+	return block.Type, block.Bytes, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+)
+
+const (
+	// PSSSaltLengthAuto causes SignPSS to use the maximum salt length that fits the key
+	// and hash, and VerifyPSS to accept any salt length found in the signature.
+	PSSSaltLengthAuto = 0
+	// PSSSaltLengthEqualsHash causes SignPSS to use a salt the same length as the hash.
+	PSSSaltLengthEqualsHash = -1
+)
+
+// PSSOptions mirrors crypto/rsa.PSSOptions and carries the salt length and hash algorithm
+// for SignPSS/VerifyPSS, and is also accepted as the opts argument of PrivateKey.Sign.
+type PSSOptions struct {
+	SaltLength int
+	Hash       crypto.Hash
+}
+
+// HashFunc lets *PSSOptions satisfy crypto.SignerOpts.
+func (opts *PSSOptions) HashFunc() crypto.Hash {
+	return opts.Hash
+}
+
+func (opts *PSSOptions) saltLength() int {
+	if opts == nil {
+		return PSSSaltLengthAuto
+	}
+	return opts.SaltLength
+}
+
+// SignPSS signs digest (the output of hash) with priv using RSASSA-PSS as specified in
+// PKCS#1 v2.1.
+func SignPSS(random io.Reader, priv PrivateKey, hash crypto.Hash, digest []byte, opts *PSSOptions) ([]byte, error) {
+	saltLength := opts.saltLength()
+	hLen := hash.New().Size()
+	k := (priv.n.BitLen() + 7) / 8
+	emBits := priv.n.BitLen() - 1
+	emLen := (emBits + 7) / 8
+
+	switch saltLength {
+	case PSSSaltLengthEqualsHash:
+		saltLength = hLen
+	case PSSSaltLengthAuto:
+		saltLength = emLen - hLen - 2
+	}
+	if saltLength < 0 {
+		return nil, errors.New("rsa-toy: key size too small for PSS with this hash")
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return nil, err
+	}
+
+	em, err := emsaPSSEncode(digest, emBits, salt, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(big.Int).SetBytes(em)
+	s := decryptCRT(priv, m)
+
+	return leftPad(s.Bytes(), k), nil
+}
+
+// VerifyPSS verifies a PSS signature of digest (the output of hash) under pub.
+func VerifyPSS(pub PublicKey, hash crypto.Hash, digest []byte, sig []byte, opts *PSSOptions) error {
+	k := (pub.n.BitLen() + 7) / 8
+	if len(sig) != k {
+		return ErrVerification
+	}
+
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(pub.n) >= 0 {
+		return ErrVerification
+	}
+
+	emBits := pub.n.BitLen() - 1
+	emLen := (emBits + 7) / 8
+
+	m := expMod(s, pub.e, pub.n)
+	em := leftPad(m.Bytes(), emLen)
+
+	if err := emsaPSSVerify(digest, em, emBits, opts.saltLength(), hash); err != nil {
+		return ErrVerification
+	}
+
+	return nil
+}
+
+// emsaPSSEncode builds the EM value described in RFC 8017 section 9.1.1.
+func emsaPSSEncode(mHash []byte, emBits int, salt []byte, hash crypto.Hash) ([]byte, error) {
+	hLen := hash.New().Size()
+	sLen := len(salt)
+	emLen := (emBits + 7) / 8
+
+	if emLen < hLen+sLen+2 {
+		return nil, errors.New("rsa-toy: key size too small for PSS with this hash and salt length")
+	}
+
+	h := hash.New()
+	h.Write(make([]byte, 8))
+	h.Write(mHash)
+	h.Write(salt)
+	hSum := h.Sum(nil)
+
+	db := make([]byte, emLen-hLen-1)
+	db[len(db)-sLen-1] = 1
+	copy(db[len(db)-sLen:], salt)
+
+	dbMask := mgf1(hSum, len(db), hash.New())
+	xorBytes(db, dbMask)
+
+	// Clear the leftmost bits that don't belong to emBits.
+	db[0] &= 0xff >> uint(8*emLen-emBits)
+
+	em := make([]byte, emLen)
+	copy(em, db)
+	copy(em[len(db):], hSum)
+	em[emLen-1] = 0xbc
+
+	return em, nil
+}
+
+// emsaPSSVerify checks em against the expected PSS encoding of mHash. saltLength of
+// PSSSaltLengthAuto accepts whatever salt length the signature actually used.
+func emsaPSSVerify(mHash, em []byte, emBits, saltLength int, hash crypto.Hash) error {
+	hLen := hash.New().Size()
+	emLen := (emBits + 7) / 8
+
+	if emLen < hLen+2 || em[emLen-1] != 0xbc {
+		return ErrVerification
+	}
+
+	db := em[:emLen-hLen-1]
+	hSum := em[emLen-hLen-1 : emLen-1]
+
+	if em[0]&^(0xff>>uint(8*emLen-emBits)) != 0 {
+		return ErrVerification
+	}
+
+	dbMask := mgf1(hSum, len(db), hash.New())
+	xorBytes(db, dbMask)
+	db[0] &= 0xff >> uint(8*emLen-emBits)
+
+	i := 0
+	for i < len(db) && db[i] == 0 {
+		i++
+	}
+	if i == len(db) || db[i] != 1 {
+		return ErrVerification
+	}
+
+	salt := db[i+1:]
+	if saltLength != PSSSaltLengthAuto && len(salt) != effectiveSaltLength(saltLength, hLen) {
+		return ErrVerification
+	}
+
+	h := hash.New()
+	h.Write(make([]byte, 8))
+	h.Write(mHash)
+	h.Write(salt)
+	want := h.Sum(nil)
+
+	if subtle.ConstantTimeCompare(hSum, want) != 1 {
+		return ErrVerification
+	}
+
+	return nil
+}
+
+func effectiveSaltLength(saltLength, hLen int) int {
+	if saltLength == PSSSaltLengthEqualsHash {
+		return hLen
+	}
+	return saltLength
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// ErrVerification is returned whenever a signature fails to verify, whatever the underlying
+// reason. As with ErrDecryption, every failure collapses into this one generic error so that
+// callers (and attackers) cannot distinguish "bad padding" from "bad hash" from "bad length".
+var ErrVerification = errors.New("rsa-toy: verification error")
+
+// hashPrefixes holds the DER encoding of the DigestInfo ASN.1 prefix for each hash
+// algorithm, as defined by PKCS#1. SignPKCS1v15 and VerifyPKCS1v15 splice the raw digest
+// after this prefix to build the DigestInfo that gets padded and signed.
+var hashPrefixes = map[crypto.Hash][]byte{
+	crypto.MD5:    {0x30, 0x20, 0x30, 0x0c, 0x06, 0x08, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x0d, 0x02, 0x05, 0x05, 0x00, 0x04, 0x10},
+	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA224: {0x30, 0x2d, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x04, 0x05, 0x00, 0x04, 0x1c},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// SignPKCS1v15 signs hashed (the output of hash, or of any hash if hash is 0) with priv,
+// using the classic PKCS#1 v1.5 padding: 0x00 0x01 0xFF..0xFF 0x00 DigestInfo.
+func SignPKCS1v15(random io.Reader, priv PrivateKey, hash crypto.Hash, hashed []byte) ([]byte, error) {
+	prefix, ok := hashPrefixes[hash]
+	if !ok {
+		return nil, errors.New("rsa-toy: unsupported hash for PKCS1v15 signing")
+	}
+
+	k := (priv.n.BitLen() + 7) / 8
+	tLen := len(prefix) + len(hashed)
+	if k < tLen+11 {
+		return nil, errors.New("rsa-toy: message too long for RSA key size")
+	}
+
+	em := make([]byte, k)
+	em[1] = 1
+	for i := 2; i < k-tLen-1; i++ {
+		em[i] = 0xff
+	}
+	copy(em[k-tLen:k-len(hashed)], prefix)
+	copy(em[k-len(hashed):], hashed)
+
+	m := new(big.Int).SetBytes(em)
+	s := decryptCRT(priv, m)
+
+	return leftPad(s.Bytes(), k), nil
+}
+
+// VerifyPKCS1v15 reverses SignPKCS1v15, returning nil if sig is a valid PKCS#1 v1.5
+// signature of hashed under pub, or ErrVerification otherwise.
+func VerifyPKCS1v15(pub PublicKey, hash crypto.Hash, hashed, sig []byte) error {
+	prefix, ok := hashPrefixes[hash]
+	if !ok {
+		return errors.New("rsa-toy: unsupported hash for PKCS1v15 verification")
+	}
+
+	k := (pub.n.BitLen() + 7) / 8
+	if len(sig) != k {
+		return ErrVerification
+	}
+
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(pub.n) >= 0 {
+		return ErrVerification
+	}
+
+	m := expMod(s, pub.e, pub.n)
+	em := leftPad(m.Bytes(), k)
+
+	tLen := len(prefix) + len(hashed)
+	want := make([]byte, k)
+	want[1] = 1
+	for i := 2; i < k-tLen-1; i++ {
+		want[i] = 0xff
+	}
+	copy(want[k-tLen:k-len(hashed)], prefix)
+	copy(want[k-len(hashed):], hashed)
+
+	if subtle.ConstantTimeCompare(em, want) != 1 {
+		return ErrVerification
+	}
+
+	return nil
+}
+
+// Sign implements crypto.Signer, dispatching to SignPKCS1v15 or SignPSS depending on the
+// concrete type of opts, the same way crypto/rsa.PrivateKey does.
+func (priv *PrivateKey) Sign(random io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if pssOpts, ok := opts.(*PSSOptions); ok {
+		return SignPSS(random, *priv, opts.HashFunc(), digest, pssOpts)
+	}
+	return SignPKCS1v15(random, *priv, opts.HashFunc(), digest)
+}
+
+// Public implements crypto.Signer.
+func (priv *PrivateKey) Public() crypto.PublicKey {
+	return priv.PublicKey
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestSignVerifyPKCS1v15(t *testing.T) {
+	private, public, err := GenerateKeysPairBits(1024)
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
+
+	h := sha256.Sum256([]byte("message to sign"))
+
+	sig, err := SignPKCS1v15(rand.Reader, private, crypto.SHA256, h[:])
+	if err != nil {
+		t.Fatal("SignPKCS1v15 failed: ", err)
+	}
+
+	if err := VerifyPKCS1v15(public, crypto.SHA256, h[:], sig); err != nil {
+		t.Errorf("VerifyPKCS1v15 failed: %v", err)
+	}
+
+	h2 := sha256.Sum256([]byte("a different message"))
+	if err := VerifyPKCS1v15(public, crypto.SHA256, h2[:], sig); err != ErrVerification {
+		t.Errorf("expected ErrVerification for tampered digest, got %v", err)
+	}
+}
+
+func TestSignVerifyPSS(t *testing.T) {
+	private, public, err := GenerateKeysPairBits(1024)
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
+
+	h := sha256.Sum256([]byte("message to sign"))
+	opts := &PSSOptions{SaltLength: PSSSaltLengthAuto, Hash: crypto.SHA256}
+
+	sig, err := SignPSS(rand.Reader, private, crypto.SHA256, h[:], opts)
+	if err != nil {
+		t.Fatal("SignPSS failed: ", err)
+	}
+
+	if err := VerifyPSS(public, crypto.SHA256, h[:], sig, opts); err != nil {
+		t.Errorf("VerifyPSS failed: %v", err)
+	}
+
+	h2 := sha256.Sum256([]byte("a different message"))
+	if err := VerifyPSS(public, crypto.SHA256, h2[:], sig, opts); err != ErrVerification {
+		t.Errorf("expected ErrVerification for tampered digest, got %v", err)
+	}
+}
+
+func TestPrivateKeyImplementsCryptoSigner(t *testing.T) {
+	private, _, err := GenerateKeysPairBits(1024)
+	if err != nil {
+		t.Fatal("Failed to generate keys pair: ", err)
+	}
+
+	var signer crypto.Signer = &private
+
+	h := sha256.Sum256([]byte("message to sign"))
+	sig, err := signer.Sign(rand.Reader, h[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal("Sign failed: ", err)
+	}
+
+	pub, ok := signer.Public().(PublicKey)
+	if !ok {
+		t.Fatal("Public() did not return a PublicKey")
+	}
+
+	if err := VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig); err != nil {
+		t.Errorf("VerifyPKCS1v15 failed: %v", err)
+	}
+}